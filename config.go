@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FeedConfig describes a single feed source and how to extract a verse from its items.
+type FeedConfig struct {
+	Name      string `yaml:"name" json:"name"`
+	URL       string `yaml:"url" json:"url"`
+	Extractor string `yaml:"extractor" json:"extractor"`                   // "blockquote", "css", or "full"
+	Selector  string `yaml:"selector,omitempty" json:"selector,omitempty"` // required for "css"
+}
+
+// Config is the top-level configuration for the service: the set of feeds it serves.
+type Config struct {
+	Feeds []FeedConfig `yaml:"feeds" json:"feeds"`
+}
+
+// defaultConfig reproduces the service's original hardcoded behavior: a single
+// Fighter Verses feed extracted via the blockquote extractor.
+func defaultConfig() *Config {
+	return &Config{
+		Feeds: []FeedConfig{
+			{
+				Name:      "fighterverses",
+				URL:       "https://www.fighterverses.com/blog-feed.xml",
+				Extractor: "blockquote",
+			},
+		},
+	}
+}
+
+// LoadConfig loads feed configuration from the file at path (YAML or JSON,
+// inferred from the extension). If path is empty, it falls back to the
+// VERSE_CONFIG_FILE environment variable, and if that's unset too, it
+// returns the default single-feed configuration.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("VERSE_CONFIG_FILE")
+	}
+	if path == "" {
+		return defaultConfig(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	var cfg Config
+	if strings.HasSuffix(path, ".json") {
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("error parsing JSON config: %v", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, fmt.Errorf("error parsing YAML config: %v", err)
+		}
+	}
+
+	if len(cfg.Feeds) == 0 {
+		return nil, fmt.Errorf("config %s defines no feeds", path)
+	}
+
+	return &cfg, nil
+}
+
+// Feed looks up a feed configuration by name.
+func (c *Config) Feed(name string) (FeedConfig, bool) {
+	for _, f := range c.Feeds {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return FeedConfig{}, false
+}
+
+// Default returns the first configured feed, used when no ?feed= is given.
+func (c *Config) Default() FeedConfig {
+	return c.Feeds[0]
+}
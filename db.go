@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// defaultRetention is how long verse history is kept before pruning, when no
+// retention window is configured.
+const defaultRetention = 365 * 24 * time.Hour
+
+const schema = `
+CREATE TABLE IF NOT EXISTS verses (
+	hash       TEXT PRIMARY KEY,
+	feed_name  TEXT NOT NULL,
+	url        TEXT NOT NULL,
+	title      TEXT,
+	pub_date   TIMESTAMP,
+	verse      TEXT NOT NULL,
+	raw_html   TEXT,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS verses_url_idx ON verses(url);
+CREATE INDEX IF NOT EXISTS verses_pub_date_idx ON verses(pub_date);
+`
+
+// VerseRecord is a single persisted verse, as returned by the browsing API.
+type VerseRecord struct {
+	Hash      string    `json:"id"`
+	FeedName  string    `json:"feed_name"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	PubDate   time.Time `json:"pub_date"`
+	Verse     string    `json:"verse"`
+	RawHTML   string    `json:"raw_html"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VerseHash deterministically identifies a verse by its feed, URL and text,
+// so the same verse seen again (e.g. after a conditional-GET miss) dedupes.
+func VerseHash(feedName, url, verse string) string {
+	sum := sha256.Sum256([]byte(feedName + "\x00" + url + "\x00" + verse))
+	return hex.EncodeToString(sum[:])
+}
+
+// DB is the persistence layer for verse history, backed by SQLite by
+// default or Postgres when configured.
+type DB struct {
+	conn   *sql.DB
+	driver string
+}
+
+// OpenDB opens (and migrates) the verse history database. driver is
+// "sqlite" (the default) or "postgres"; dsn defaults to a local
+// "verses.db" file for sqlite.
+func OpenDB(driver, dsn string) (*DB, error) {
+	switch driver {
+	case "":
+		driver = "sqlite"
+		fallthrough
+	case "sqlite":
+		if dsn == "" {
+			dsn = "verses.db"
+		}
+	case "postgres":
+		// dsn is required; leave as given.
+	default:
+		return nil, fmt.Errorf("unknown db driver %q", driver)
+	}
+
+	conn, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s database: %v", driver, err)
+	}
+
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error migrating %s database: %v", driver, err)
+	}
+
+	return &DB{conn: conn, driver: driver}, nil
+}
+
+// Close closes the underlying database connection.
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
+
+// Insert records a verse if it hasn't been seen before (by hash). It reports
+// whether a new row was inserted.
+func (db *DB) Insert(ctx context.Context, v VerseRecord) (bool, error) {
+	var query string
+	if db.driver == "postgres" {
+		query = `INSERT INTO verses (hash, feed_name, url, title, pub_date, verse, raw_html, created_at)
+		          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (hash) DO NOTHING`
+	} else {
+		query = `INSERT OR IGNORE INTO verses (hash, feed_name, url, title, pub_date, verse, raw_html, created_at)
+		          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	}
+
+	res, err := db.conn.ExecContext(ctx, query, v.Hash, v.FeedName, v.URL, v.Title, v.PubDate, v.Verse, v.RawHTML, v.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("error inserting verse: %v", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking insert result: %v", err)
+	}
+	return n > 0, nil
+}
+
+// Prune deletes verses older than retention, returning the number removed.
+// Retention is measured from created_at (when we ingested the verse), not
+// pub_date, since pub_date is upstream-controlled and may be the zero value
+// for feed items with no parseable publish date.
+func (db *DB) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+
+	query := "DELETE FROM verses WHERE created_at < ?"
+	if db.driver == "postgres" {
+		query = "DELETE FROM verses WHERE created_at < $1"
+	}
+
+	res, err := db.conn.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning verses: %v", err)
+	}
+	return res.RowsAffected()
+}
+
+// List returns verses ordered newest-first, paginated by limit/offset.
+func (db *DB) List(ctx context.Context, limit, offset int) ([]VerseRecord, error) {
+	query := "SELECT hash, feed_name, url, title, pub_date, verse, raw_html, created_at FROM verses ORDER BY pub_date DESC LIMIT ? OFFSET ?"
+	if db.driver == "postgres" {
+		query = "SELECT hash, feed_name, url, title, pub_date, verse, raw_html, created_at FROM verses ORDER BY pub_date DESC LIMIT $1 OFFSET $2"
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing verses: %v", err)
+	}
+	defer rows.Close()
+
+	return scanVerses(rows)
+}
+
+// Get returns a single verse by its hash id.
+func (db *DB) Get(ctx context.Context, id string) (VerseRecord, bool, error) {
+	query := "SELECT hash, feed_name, url, title, pub_date, verse, raw_html, created_at FROM verses WHERE hash = ?"
+	if db.driver == "postgres" {
+		query = "SELECT hash, feed_name, url, title, pub_date, verse, raw_html, created_at FROM verses WHERE hash = $1"
+	}
+
+	row := db.conn.QueryRowContext(ctx, query, id)
+	v, err := scanVerse(row)
+	if err == sql.ErrNoRows {
+		return VerseRecord{}, false, nil
+	}
+	if err != nil {
+		return VerseRecord{}, false, fmt.Errorf("error fetching verse %q: %v", id, err)
+	}
+	return v, true, nil
+}
+
+// Random returns a single randomly-selected verse.
+func (db *DB) Random(ctx context.Context) (VerseRecord, bool, error) {
+	query := "SELECT hash, feed_name, url, title, pub_date, verse, raw_html, created_at FROM verses ORDER BY RANDOM() LIMIT 1"
+
+	row := db.conn.QueryRowContext(ctx, query)
+	v, err := scanVerse(row)
+	if err == sql.ErrNoRows {
+		return VerseRecord{}, false, nil
+	}
+	if err != nil {
+		return VerseRecord{}, false, fmt.Errorf("error fetching random verse: %v", err)
+	}
+	return v, true, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanVerse(row rowScanner) (VerseRecord, error) {
+	var v VerseRecord
+	var title, rawHTML sql.NullString
+	var pubDate sql.NullTime
+
+	err := row.Scan(&v.Hash, &v.FeedName, &v.URL, &title, &pubDate, &v.Verse, &rawHTML, &v.CreatedAt)
+	if err != nil {
+		return VerseRecord{}, err
+	}
+
+	v.Title = title.String
+	v.RawHTML = rawHTML.String
+	v.PubDate = pubDate.Time
+	return v, nil
+}
+
+func scanVerses(rows *sql.Rows) ([]VerseRecord, error) {
+	var out []VerseRecord
+	for rows.Next() {
+		v, err := scanVerse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning verse row: %v", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := OpenDB("sqlite", filepath.Join(t.TempDir(), "verses.db"))
+	if err != nil {
+		t.Fatalf("OpenDB returned error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDBInsertDedupesByHash(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	record := VerseRecord{
+		Hash:      VerseHash("fighterverses", "https://example.com/1", "verse text"),
+		FeedName:  "fighterverses",
+		URL:       "https://example.com/1",
+		Title:     "Post 1",
+		PubDate:   time.Now(),
+		Verse:     "verse text",
+		RawHTML:   "<p>verse text</p>",
+		CreatedAt: time.Now(),
+	}
+
+	inserted, err := db.Insert(ctx, record)
+	if err != nil {
+		t.Fatalf("Insert returned error: %v", err)
+	}
+	if !inserted {
+		t.Fatal("expected the first insert to report a new row")
+	}
+
+	inserted, err = db.Insert(ctx, record)
+	if err != nil {
+		t.Fatalf("Insert returned error: %v", err)
+	}
+	if inserted {
+		t.Fatal("expected a duplicate hash to be ignored")
+	}
+
+	verses, err := db.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(verses) != 1 {
+		t.Fatalf("List returned %d verses, want 1", len(verses))
+	}
+}
+
+func TestDBGetAndRandom(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	record := VerseRecord{
+		Hash:      VerseHash("fighterverses", "https://example.com/1", "verse text"),
+		FeedName:  "fighterverses",
+		URL:       "https://example.com/1",
+		Verse:     "verse text",
+		PubDate:   time.Now(),
+		CreatedAt: time.Now(),
+	}
+	if _, err := db.Insert(ctx, record); err != nil {
+		t.Fatalf("Insert returned error: %v", err)
+	}
+
+	got, ok, err := db.Get(ctx, record.Hash)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected to find the inserted verse")
+	}
+	if got.Verse != record.Verse {
+		t.Errorf("Get() verse = %q, want %q", got.Verse, record.Verse)
+	}
+
+	if _, ok, err := db.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("Get(missing) = (ok=%v, err=%v), want (false, nil)", ok, err)
+	}
+
+	random, ok, err := db.Random(ctx)
+	if err != nil {
+		t.Fatalf("Random returned error: %v", err)
+	}
+	if !ok || random.Hash != record.Hash {
+		t.Fatalf("Random() = %+v, want the only inserted verse", random)
+	}
+}
+
+func TestDBPrune(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	old := VerseRecord{
+		Hash:      VerseHash("fighterverses", "https://example.com/old", "old verse"),
+		FeedName:  "fighterverses",
+		URL:       "https://example.com/old",
+		Verse:     "old verse",
+		PubDate:   time.Now(),
+		CreatedAt: time.Now().Add(-400 * 24 * time.Hour),
+	}
+	recent := VerseRecord{
+		Hash:      VerseHash("fighterverses", "https://example.com/new", "new verse"),
+		FeedName:  "fighterverses",
+		URL:       "https://example.com/new",
+		Verse:     "new verse",
+		PubDate:   time.Time{},
+		CreatedAt: time.Now(),
+	}
+	if _, err := db.Insert(ctx, old); err != nil {
+		t.Fatalf("Insert returned error: %v", err)
+	}
+	if _, err := db.Insert(ctx, recent); err != nil {
+		t.Fatalf("Insert returned error: %v", err)
+	}
+
+	n, err := db.Prune(ctx, defaultRetention)
+	if err != nil {
+		t.Fatalf("Prune returned error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Prune removed %d rows, want 1", n)
+	}
+
+	verses, err := db.List(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(verses) != 1 || verses[0].Hash != recent.Hash {
+		t.Fatalf("List() = %+v, want only the recent verse", verses)
+	}
+}
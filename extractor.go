@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Extractor pulls the verse text out of a feed item.
+type Extractor interface {
+	Extract(item *gofeed.Item) (string, error)
+}
+
+// BlockquoteExtractor extracts the first <blockquote> found in the item's
+// content, reproducing the service's original Fighter Verses behavior.
+type BlockquoteExtractor struct{}
+
+func (BlockquoteExtractor) Extract(item *gofeed.Item) (string, error) {
+	return ExtractBlockquote(itemContent(item))
+}
+
+// CSSSelectorExtractor extracts the text of the first element matching an
+// arbitrary CSS selector, e.g. "blockquote.verse".
+type CSSSelectorExtractor struct {
+	Selector string
+}
+
+func (e CSSSelectorExtractor) Extract(item *gofeed.Item) (string, error) {
+	return ExtractSelector(itemContent(item), e.Selector)
+}
+
+// FullContentExtractor returns the item's entire content with HTML tags
+// stripped, for feeds that don't isolate the verse in a dedicated element.
+type FullContentExtractor struct{}
+
+func (FullContentExtractor) Extract(item *gofeed.Item) (string, error) {
+	content := itemContent(item)
+	if content == "" {
+		return "", fmt.Errorf("item has no content")
+	}
+	return ExtractText(content)
+}
+
+// itemContent picks the richest content available on a gofeed.Item, falling
+// back from the full content to the description/summary.
+func itemContent(item *gofeed.Item) string {
+	if item.Content != "" {
+		return item.Content
+	}
+	return item.Description
+}
+
+// newExtractor builds the Extractor configured for a feed.
+func newExtractor(cfg FeedConfig) (Extractor, error) {
+	switch cfg.Extractor {
+	case "", "blockquote":
+		return BlockquoteExtractor{}, nil
+	case "css":
+		if cfg.Selector == "" {
+			return nil, fmt.Errorf("feed %q uses the css extractor but has no selector configured", cfg.Name)
+		}
+		return CSSSelectorExtractor{Selector: cfg.Selector}, nil
+	case "full":
+		return FullContentExtractor{}, nil
+	default:
+		return nil, fmt.Errorf("feed %q has unknown extractor %q", cfg.Name, cfg.Extractor)
+	}
+}
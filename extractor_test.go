@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestBlockquoteExtractor(t *testing.T) {
+	item := &gofeed.Item{Content: loadFixture(t, "fighterverses_post.html")}
+
+	got, err := BlockquoteExtractor{}.Extract(item)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	want := "\"It is not the mountain we conquer,\nbut ourselves.\"\n— Sir Edmund Hillary"
+	if got != want {
+		t.Errorf("Extract() = %q, want %q", got, want)
+	}
+}
+
+func TestFullContentExtractor(t *testing.T) {
+	item := &gofeed.Item{Content: "<p>Rock &amp; roll</p>"}
+
+	got, err := FullContentExtractor{}.Extract(item)
+	if err != nil {
+		t.Fatalf("Extract returned error: %v", err)
+	}
+
+	if got != "Rock & roll" {
+		t.Errorf("Extract() = %q, want %q", got, "Rock & roll")
+	}
+}
+
+func TestFullContentExtractorEmptyItem(t *testing.T) {
+	item := &gofeed.Item{}
+
+	if _, err := (FullContentExtractor{}).Extract(item); err == nil {
+		t.Fatal("expected an error for an item with no content")
+	}
+}
+
+func TestNewExtractorUnknownKind(t *testing.T) {
+	if _, err := newExtractor(FeedConfig{Name: "x", Extractor: "bogus"}); err == nil {
+		t.Fatal("expected an error for an unknown extractor kind")
+	}
+}
+
+func TestNewExtractorCSSRequiresSelector(t *testing.T) {
+	if _, err := newExtractor(FeedConfig{Name: "x", Extractor: "css"}); err == nil {
+		t.Fatal("expected an error when the css extractor has no selector")
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// defaultRefreshInterval is how often feeds are re-pulled in the background
+// when no interval is configured.
+const defaultRefreshInterval = 15 * time.Minute
+
+// VerseItem is one extracted verse, keyed to the upstream feed item it came
+// from, kept around so /verse can be served as a feed of past verses.
+type VerseItem struct {
+	Title   string
+	Link    string
+	Author  string
+	PubDate time.Time
+	Verse   string
+}
+
+// storedFeed holds the extracted verse for one configured feed, along with
+// the caching metadata needed for conditional GETs against the upstream feed.
+type storedFeed struct {
+	cfg         FeedConfig
+	verse       string
+	history     []VerseItem
+	etag        string
+	lastMod     string
+	lastUpdated time.Time
+	err         error
+}
+
+// FeedStore holds the most recently extracted verse for each configured
+// feed, refreshed in the background so verseHandler never blocks on the
+// upstream fetch. When db is set, every newly-extracted verse is also
+// persisted there for later browsing.
+type FeedStore struct {
+	mu        sync.RWMutex
+	feeds     map[string]*storedFeed
+	db        *DB
+	retention time.Duration
+}
+
+// NewFeedStore creates an empty store for the given config's feeds. db may
+// be nil to run without persistence.
+func NewFeedStore(cfg *Config, db *DB, retention time.Duration) *FeedStore {
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+	s := &FeedStore{
+		feeds:     make(map[string]*storedFeed, len(cfg.Feeds)),
+		db:        db,
+		retention: retention,
+	}
+	for _, f := range cfg.Feeds {
+		s.feeds[f.Name] = &storedFeed{cfg: f}
+	}
+	return s
+}
+
+// Get returns the current cached state for a feed by name. The returned
+// history is a copy, safe to read after the lock is released even while
+// the background refresher is concurrently updating the live entry.
+func (s *FeedStore) Get(name string) (storedFeed, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.feeds[name]
+	if !ok {
+		return storedFeed{}, false
+	}
+	cp := *f
+	cp.history = append([]VerseItem(nil), f.history...)
+	return cp, true
+}
+
+// RefreshAll re-pulls every configured feed, logging (but not failing on)
+// per-feed errors so one broken feed doesn't block the others.
+func (s *FeedStore) RefreshAll(ctx context.Context) {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.feeds))
+	for name := range s.feeds {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+
+	for _, name := range names {
+		if err := s.Refresh(ctx, name); err != nil {
+			slog.Error("error refreshing feed", "feed", name, "error", err)
+		}
+	}
+
+	if s.db != nil {
+		if n, err := s.db.Prune(ctx, s.retention); err != nil {
+			slog.Error("error pruning verse history", "error", err)
+		} else if n > 0 {
+			slog.Info("pruned verse history", "count", n, "retention", s.retention)
+		}
+	}
+}
+
+// Refresh re-pulls a single feed by name, honoring conditional GET: if the
+// upstream responds 304 Not Modified, the cached verse is left untouched.
+func (s *FeedStore) Refresh(ctx context.Context, name string) error {
+	s.mu.RLock()
+	entry, ok := s.feeds[name]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown feed %q", name)
+	}
+
+	s.mu.RLock()
+	cfg, etag, lastMod := entry.cfg, entry.etag, entry.lastMod
+	s.mu.RUnlock()
+
+	extractor, err := newExtractor(cfg)
+	if err != nil {
+		s.recordErr(name, err)
+		return err
+	}
+
+	feed, newETag, newLastMod, notModified, err := fetchFeed(ctx, cfg, etag, lastMod)
+	if err != nil {
+		s.recordErr(name, err)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if notModified {
+		entry.lastUpdated = time.Now()
+		entry.err = nil
+		return nil
+	}
+
+	if len(feed.Items) == 0 {
+		err := fmt.Errorf("no items found in feed %q", name)
+		fetchErrorsTotal.WithLabelValues(name, "empty_feed").Inc()
+		entry.err = err
+		return err
+	}
+
+	item := feed.Items[0]
+	verse, err := extractor.Extract(item)
+	if err != nil {
+		kind := "extract"
+		if cfg.Extractor == "" || cfg.Extractor == "blockquote" {
+			kind = "no_blockquote"
+		}
+		fetchErrorsTotal.WithLabelValues(name, kind).Inc()
+		entry.err = err
+		return err
+	}
+
+	entry.verse = verse
+	entry.etag = newETag
+	entry.lastMod = newLastMod
+	entry.lastUpdated = time.Now()
+	entry.err = nil
+
+	vi := VerseItem{
+		Title:   item.Title,
+		Link:    item.Link,
+		Author:  itemAuthor(item),
+		PubDate: itemPubDate(item),
+		Verse:   verse,
+	}
+	if len(entry.history) == 0 || entry.history[0].Link != vi.Link {
+		entry.history = append([]VerseItem{vi}, entry.history...)
+	} else {
+		entry.history = append([]VerseItem{vi}, entry.history[1:]...)
+	}
+
+	if s.db != nil {
+		record := VerseRecord{
+			Hash:      VerseHash(name, item.Link, verse),
+			FeedName:  name,
+			URL:       item.Link,
+			Title:     item.Title,
+			PubDate:   vi.PubDate,
+			Verse:     verse,
+			RawHTML:   itemContent(item),
+			CreatedAt: time.Now(),
+		}
+		if _, err := s.db.Insert(ctx, record); err != nil {
+			slog.Error("error persisting verse", "feed", name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// itemAuthor picks a display name for the item's author, if any.
+func itemAuthor(item *gofeed.Item) string {
+	if item.Author != nil {
+		return item.Author.Name
+	}
+	if len(item.Authors) > 0 {
+		return item.Authors[0].Name
+	}
+	return ""
+}
+
+// itemPubDate resolves the item's publish date, falling back to parsing the
+// raw Published string when gofeed couldn't parse it itself.
+func itemPubDate(item *gofeed.Item) time.Time {
+	if item.PublishedParsed != nil {
+		return *item.PublishedParsed
+	}
+	if item.Published != "" {
+		if t, err := time.Parse(time.RFC1123Z, item.Published); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *FeedStore) recordErr(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.feeds[name]; ok {
+		entry.err = err
+	}
+}
+
+// StartRefresher launches a background goroutine that refreshes all feeds
+// immediately and then on a fixed interval, until ctx is done.
+func (s *FeedStore) StartRefresher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+
+	s.RefreshAll(ctx)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RefreshAll(ctx)
+			}
+		}
+	}()
+}
+
+// fetchFeed fetches and parses a feed, sending If-None-Match / If-Modified-Since
+// headers when prior caching metadata is available. A 304 response is
+// reported via the notModified return value with a nil feed.
+func fetchFeed(ctx context.Context, cfg FeedConfig, etag, lastMod string) (feed *gofeed.Feed, newETag, newLastMod string, notModified bool, err error) {
+	start := time.Now()
+	defer func() { fetchDuration.WithLabelValues(cfg.Name).Observe(time.Since(start).Seconds()) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		fetchErrorsTotal.WithLabelValues(cfg.Name, "network").Inc()
+		return nil, "", "", false, fmt.Errorf("error building request for feed %q: %v", cfg.Name, err)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastMod != "" {
+		req.Header.Set("If-Modified-Since", lastMod)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fetchErrorsTotal.WithLabelValues(cfg.Name, "network").Inc()
+		return nil, "", "", false, fmt.Errorf("error fetching feed %q: %v", cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		cacheResultsTotal.WithLabelValues(cfg.Name, "hit").Inc()
+		upstreamNotModifiedTotal.WithLabelValues(cfg.Name).Inc()
+		return nil, etag, lastMod, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		fetchErrorsTotal.WithLabelValues(cfg.Name, "non_200").Inc()
+		return nil, "", "", false, fmt.Errorf("received status code %d fetching feed %q", resp.StatusCode, cfg.Name)
+	}
+
+	fp := gofeed.NewParser()
+	parsed, err := fp.Parse(resp.Body)
+	if err != nil {
+		fetchErrorsTotal.WithLabelValues(cfg.Name, "parse").Inc()
+		return nil, "", "", false, fmt.Errorf("error parsing feed %q: %v", cfg.Name, err)
+	}
+
+	cacheResultsTotal.WithLabelValues(cfg.Name, "miss").Inc()
+	return parsed, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), false, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// parseFragment parses an HTML fragment (as found in an RSS/Atom
+// content:encoded value) as if it were the children of a <body> element.
+func parseFragment(fragment string) ([]*html.Node, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing HTML: %v", err)
+	}
+	return nodes, nil
+}
+
+// findFirst returns the first descendant (or root) node with the given tag
+// name, in document order.
+func findFirst(nodes []*html.Node, tag string) *html.Node {
+	var found *html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if found != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == tag {
+			found = n
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return found
+}
+
+// attrVal returns the value of an element's attribute, or "" if absent.
+func attrVal(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// TextExtractor walks an HTML fragment's nodes and renders their text
+// content: <br> becomes a newline, <p>/<div> boundaries get a trailing
+// newline, <img> contributes its alt text, and all other elements are
+// unwrapped down to their text. Entities are decoded via html.UnescapeString
+// as part of parsing.
+func TextExtractor(nodes []*html.Node) string {
+	var buf strings.Builder
+	for _, n := range nodes {
+		walkText(n, &buf)
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(buf.String(), "\n\n"))
+}
+
+func walkText(n *html.Node, buf *strings.Builder) {
+	switch n.Type {
+	case html.TextNode:
+		buf.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		switch n.Data {
+		case "br":
+			buf.WriteString("\n")
+			return
+		case "img":
+			buf.WriteString(attrVal(n, "alt"))
+			return
+		case "script", "style":
+			return
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkText(c, buf)
+	}
+
+	if n.Type == html.ElementNode && (n.Data == "p" || n.Data == "div" || n.Data == "blockquote") {
+		buf.WriteString("\n")
+	}
+}
+
+// ExtractText parses an HTML fragment and returns its rendered plain text.
+func ExtractText(fragment string) (string, error) {
+	nodes, err := parseFragment(fragment)
+	if err != nil {
+		return "", err
+	}
+	return TextExtractor(nodes), nil
+}
+
+// ExtractBlockquote parses an HTML fragment, locates the first <blockquote>,
+// and returns its rendered plain text.
+func ExtractBlockquote(fragment string) (string, error) {
+	nodes, err := parseFragment(fragment)
+	if err != nil {
+		return "", err
+	}
+
+	bq := findFirst(nodes, "blockquote")
+	if bq == nil {
+		return "", fmt.Errorf("no blockquote found in item")
+	}
+
+	return TextExtractor([]*html.Node{bq}), nil
+}
+
+// ExtractSelector parses an HTML fragment, locates the first element
+// matching a CSS selector (via goquery), and returns its rendered plain text.
+func ExtractSelector(fragment, selector string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return "", fmt.Errorf("error parsing item content: %v", err)
+	}
+
+	sel := doc.Find(selector).First()
+	if sel.Length() == 0 {
+		return "", fmt.Errorf("no element matching selector %q found in item", selector)
+	}
+
+	return TextExtractor(sel.Nodes), nil
+}
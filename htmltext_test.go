@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("reading fixture %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestExtractBlockquote(t *testing.T) {
+	content := loadFixture(t, "fighterverses_post.html")
+
+	got, err := ExtractBlockquote(content)
+	if err != nil {
+		t.Fatalf("ExtractBlockquote returned error: %v", err)
+	}
+
+	want := "\"It is not the mountain we conquer,\nbut ourselves.\"\n— Sir Edmund Hillary"
+	if got != want {
+		t.Errorf("ExtractBlockquote() = %q, want %q", got, want)
+	}
+}
+
+func TestExtractBlockquoteNoMatch(t *testing.T) {
+	_, err := ExtractBlockquote("<p>no quote here</p>")
+	if err == nil {
+		t.Fatal("expected an error when no blockquote is present")
+	}
+}
+
+func TestExtractSelectorMatchesBlockquote(t *testing.T) {
+	content := loadFixture(t, "fighterverses_post.html")
+
+	got, err := ExtractSelector(content, "blockquote")
+	if err != nil {
+		t.Fatalf("ExtractSelector returned error: %v", err)
+	}
+
+	want, err := ExtractBlockquote(content)
+	if err != nil {
+		t.Fatalf("ExtractBlockquote returned error: %v", err)
+	}
+
+	if got != want {
+		t.Errorf("ExtractSelector(blockquote) = %q, want %q", got, want)
+	}
+}
+
+func TestExtractTextIncludesImageAltAndParagraphBreaks(t *testing.T) {
+	content := loadFixture(t, "fighterverses_post.html")
+
+	got, err := ExtractText(content)
+	if err != nil {
+		t.Fatalf("ExtractText returned error: %v", err)
+	}
+
+	if !strings.Contains(got, "BJJ gi patch") {
+		t.Errorf("ExtractText() = %q, want it to include image alt text", got)
+	}
+	if !strings.Contains(got, "This week's verse comes from the closing exchange of a classic match.\n") {
+		t.Errorf("ExtractText() = %q, want a newline after the opening paragraph", got)
+	}
+}
+
+func TestExtractTextDecodesEntities(t *testing.T) {
+	got, err := ExtractText("<p>Rock &amp; roll &mdash; &quot;quoted&quot;</p>")
+	if err != nil {
+		t.Fatalf("ExtractText returned error: %v", err)
+	}
+
+	want := "Rock & roll — \"quoted\""
+	if got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+}
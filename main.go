@@ -1,142 +1,164 @@
 package main
 
 import (
-	"encoding/xml"
+	"context"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"regexp"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// RSS is the top-level structure for an RSS feed
-type RSS struct {
-	XMLName xml.Name `xml:"rss"`
-	Channel Channel  `xml:"channel"`
+// shutdownTimeout bounds how long graceful shutdown waits for in-flight
+// requests to drain.
+const shutdownTimeout = 10 * time.Second
+
+// verseHandler handles the request for a verse, serving the most recently
+// refreshed value from the store. The feed to use is selected via the
+// ?feed= query param, defaulting to the first configured feed.
+func verseHandler(cfg *Config, store *FeedStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("feed")
+		if name == "" {
+			name = cfg.Default().Name
+		} else if _, ok := cfg.Feed(name); !ok {
+			http.Error(w, fmt.Sprintf("unknown feed %q", name), http.StatusNotFound)
+			return
+		}
+
+		entry, ok := store.Get(name)
+		if !ok || (entry.verse == "" && entry.err != nil) {
+			msg := "verse not yet available"
+			if ok && entry.err != nil {
+				msg = entry.err.Error()
+			}
+			http.Error(w, fmt.Sprintf("Error fetching verse: %s", msg), http.StatusInternalServerError)
+			return
+		}
+
+		if entry.etag != "" {
+			w.Header().Set("ETag", entry.etag)
+		}
+		if entry.lastMod != "" {
+			w.Header().Set("Last-Modified", entry.lastMod)
+		}
+
+		format := feedFormat(r)
+		if format == "txt" {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte(entry.verse))
+			return
+		}
+
+		feed := buildFeed(entry.cfg, entry.history)
+		if err := writeFeed(w, format, feed, entry.verse); err != nil {
+			http.Error(w, fmt.Sprintf("Error rendering feed: %v", err), http.StatusInternalServerError)
+		}
+	}
 }
 
-// Channel represents the channel element in an RSS feed
-type Channel struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	Items       []Item `xml:"item"`
+// refreshHandler is a token-guarded admin endpoint that forces an immediate
+// re-pull of all configured feeds.
+func refreshHandler(store *FeedStore, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token == "" || r.Header.Get("X-Admin-Token") != token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		store.RefreshAll(r.Context())
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}
 }
 
-// Item represents an individual item in an RSS feed
-type Item struct {
-	Title          string `xml:"title"`
-	Link           string `xml:"link"`
-	Description    string `xml:"description"`
-	PubDate        string `xml:"pubDate"`
-	ContentEncoded string `xml:"encoded"`
+// healthHandler provides a simple health check endpoint
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
 }
 
-// fetchVerse gets the latest verse from the Fighter Verses RSS feed
-func fetchVerse() (string, error) {
-	// URL is hardcoded to Fighter Verses RSS feed
-	feedURL := "https://www.fighterverses.com/blog-feed.xml"
-
-	// Fetch the RSS feed
-	resp, err := http.Get(feedURL)
-	if err != nil {
-		return "", fmt.Errorf("error fetching RSS feed: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("received status code %d", resp.StatusCode)
-	}
+func main() {
+	slog.SetDefault(newLogger())
 
-	// Read the response body
-	xmlData, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("error reading response body: %v", err)
+	// Get port from environment variable or default to 8081
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8081"
 	}
 
-	// Parse the XML
-	var rss RSS
-	err = xml.Unmarshal(xmlData, &rss)
+	cfg, err := LoadConfig(os.Getenv("VERSE_CONFIG_FILE"))
 	if err != nil {
-		return "", fmt.Errorf("error parsing XML: %v", err)
+		fatal("Error loading config", err)
 	}
 
-	if len(rss.Channel.Items) == 0 {
-		return "", fmt.Errorf("no items found in the feed")
+	refreshInterval := defaultRefreshInterval
+	if v := os.Getenv("REFRESH_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fatal("Error parsing REFRESH_INTERVAL", err)
+		}
+		refreshInterval = d
 	}
 
-	// Get the most recent item (first item in the feed)
-	mostRecentItem := rss.Channel.Items[0]
-
-	// Extract blockquote using regex
-	re := regexp.MustCompile(`<blockquote>.*?</blockquote>`)
-	blockquote := re.FindString(mostRecentItem.ContentEncoded)
-
-	if blockquote == "" {
-		return "", fmt.Errorf("no blockquote found in the most recent item")
+	retention := defaultRetention
+	if v := os.Getenv("RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			fatal("Error parsing RETENTION", err)
+		}
+		retention = d
 	}
 
-	// Remove HTML tags and return only the text
-	cleanText := removeHTMLTags(blockquote)
-	return cleanText, nil
-}
-
-// removeHTMLTags removes HTML tags from a string
-func removeHTMLTags(html string) string {
-	// First, replace some common entities
-	html = strings.ReplaceAll(html, "&apos;", "'")
-	html = strings.ReplaceAll(html, "&quot;", "\"")
-	html = strings.ReplaceAll(html, "&amp;", "&")
-	html = strings.ReplaceAll(html, "&lt;", "<")
-	html = strings.ReplaceAll(html, "&gt;", ">")
-
-	// Replace <br> and variants with newlines
-	html = strings.ReplaceAll(html, "<br>", "\n")
-	html = strings.ReplaceAll(html, "<br/>", "\n")
-	html = strings.ReplaceAll(html, "<br />", "\n")
-
-	// Remove all HTML tags
-	re := regexp.MustCompile("<[^>]*>")
-	return strings.TrimSpace(re.ReplaceAllString(html, ""))
-}
-
-// verseHandler handles the request for a verse
-func verseHandler(w http.ResponseWriter, r *http.Request) {
-	verse, err := fetchVerse()
+	db, err := OpenDB(os.Getenv("DB_DRIVER"), os.Getenv("DB_DSN"))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Error fetching verse: %v", err), http.StatusInternalServerError)
-		return
+		fatal("Error opening database", err)
 	}
+	defer db.Close()
 
-	// Set plain text content type
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-
-	// Write verse as plain text
-	w.Write([]byte(verse))
-}
-
-// healthHandler provides a simple health check endpoint
-func healthHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("OK"))
-}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-func main() {
-	// Get port from environment variable or default to 8081
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8081"
-	}
+	store := NewFeedStore(cfg, db, retention)
+	store.StartRefresher(ctx, refreshInterval)
 
 	// Define routes
-	http.HandleFunc("/verse", verseHandler)
-	http.HandleFunc("/health", healthHandler)
-
-	// Start the server
-	log.Printf("Starting verse extractor service on port %s", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
-		log.Fatalf("Error starting server: %v", err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verse", instrumentRoute("verse", verseHandler(cfg, store)))
+	mux.HandleFunc("/refresh", instrumentRoute("refresh", refreshHandler(store, os.Getenv("ADMIN_TOKEN"))))
+	mux.HandleFunc("/health", instrumentRoute("health", healthHandler))
+	mux.HandleFunc("GET /verses", instrumentRoute("verses_list", versesListHandler(db)))
+	mux.HandleFunc("GET /verses/random", instrumentRoute("verses_random", verseRandomHandler(db)))
+	mux.HandleFunc("GET /verses/{id}", instrumentRoute("verses_get", verseGetHandler(db)))
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{Addr: ":" + port, Handler: mux}
+
+	go func() {
+		slog.Info("starting verse extractor service", "port", port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("Error starting server", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("error during graceful shutdown", "error", err)
 	}
 }
+
+// fatal logs err at error level and exits the process, mirroring the old
+// log.Fatalf behavior with structured output.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
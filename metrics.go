@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// fetchDuration tracks how long it takes to fetch and parse an upstream feed.
+	fetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "verse_extractor_feed_fetch_duration_seconds",
+		Help:    "Time spent fetching and parsing an upstream feed.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"feed"})
+
+	// fetchErrorsTotal counts feed refresh failures by the stage that failed:
+	// "network" (request/transport error), "non_200" (bad upstream status),
+	// "parse" (feed couldn't be parsed), "empty_feed" (the feed had no items),
+	// "no_blockquote" (the blockquote extractor found nothing to extract), or
+	// "extract" (any other extractor failed).
+	fetchErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verse_extractor_feed_fetch_errors_total",
+		Help: "Count of feed fetch/extract errors by kind.",
+	}, []string{"feed", "kind"})
+
+	// cacheResultsTotal counts refresh attempts by whether the upstream
+	// feed had changed; its ratio is this service's cache hit ratio.
+	cacheResultsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verse_extractor_cache_results_total",
+		Help: "Count of feed refresh attempts by cache result (hit = upstream 304, miss = feed re-fetched).",
+	}, []string{"feed", "result"})
+
+	// upstreamNotModifiedTotal counts 304 Not Modified responses from upstream feeds.
+	upstreamNotModifiedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "verse_extractor_upstream_not_modified_total",
+		Help: "Count of upstream feed fetches that returned 304 Not Modified.",
+	}, []string{"feed"})
+
+	// requestDuration tracks HTTP handler latency per route.
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "verse_extractor_http_request_duration_seconds",
+		Help:    "HTTP handler request duration by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
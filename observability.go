@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type requestIDKey struct{}
+
+// newLogger builds the service's structured (JSON) logger.
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, nil))
+}
+
+// statusWriter records the status code written to an http.ResponseWriter so
+// it can be reported as a metric label after the handler returns.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// instrumentRoute wraps a handler with a per-request ID, structured request
+// logging, and a Prometheus request duration histogram labeled by route.
+func instrumentRoute(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := uuid.NewString()
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next(sw, r)
+
+		elapsed := time.Since(start)
+		requestDuration.WithLabelValues(route, r.Method, strconv.Itoa(sw.status)).Observe(elapsed.Seconds())
+		slog.Info("request handled",
+			"request_id", id,
+			"route", route,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", sw.status,
+			"duration_ms", elapsed.Milliseconds(),
+		)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/feeds"
+)
+
+// buildFeed assembles a gorilla/feeds Feed of past verses for a feed config,
+// newest first.
+func buildFeed(cfg FeedConfig, history []VerseItem) *feeds.Feed {
+	f := &feeds.Feed{
+		Title:       fmt.Sprintf("%s verse of the day", cfg.Name),
+		Link:        &feeds.Link{Href: cfg.URL},
+		Description: fmt.Sprintf("Verses extracted from %s", cfg.URL),
+	}
+
+	for _, v := range history {
+		item := &feeds.Item{
+			Title:       v.Title,
+			Link:        &feeds.Link{Href: v.Link},
+			Description: v.Verse,
+			Content:     v.Verse,
+			Created:     v.PubDate,
+			Id:          v.Link,
+		}
+		if v.Author != "" {
+			item.Author = &feeds.Author{Name: v.Author}
+		}
+		f.Add(item)
+	}
+
+	if len(history) > 0 {
+		f.Updated = history[0].PubDate
+	}
+
+	return f
+}
+
+// feedFormat resolves the desired output format for a /verse request: the
+// ?format= query param takes priority, falling back to the Accept header,
+// and defaulting to plain text.
+func feedFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "application/rss+xml"):
+		return "rss"
+	case strings.Contains(accept, "application/atom+xml"):
+		return "atom"
+	case strings.Contains(accept, "application/feed+json"), strings.Contains(accept, "application/json"):
+		return "json"
+	default:
+		return "txt"
+	}
+}
+
+// writeFeed renders a feed in the requested format and writes it to w, or
+// writes the plain verse text for "txt" (the default).
+func writeFeed(w http.ResponseWriter, format string, f *feeds.Feed, plainVerse string) error {
+	switch format {
+	case "rss":
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		return f.WriteRss(w)
+	case "atom":
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		return f.WriteAtom(w)
+	case "json":
+		w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+		return f.WriteJSON(w)
+	case "txt":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, err := w.Write([]byte(plainVerse))
+		return err
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
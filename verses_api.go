@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultVersesLimit = 20
+	maxVersesLimit     = 100
+)
+
+// versesListResponse is the paginated response body for GET /verses.
+type versesListResponse struct {
+	Verses []VerseRecord `json:"verses"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+}
+
+// versesListHandler serves GET /verses: a paginated, newest-first list of
+// persisted verses, controlled by ?limit= and ?offset=.
+func versesListHandler(db *DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := defaultVersesLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		if limit > maxVersesLimit {
+			limit = maxVersesLimit
+		}
+
+		offset := 0
+		if v := r.URL.Query().Get("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			offset = n
+		}
+
+		verses, err := db.List(r.Context(), limit, offset)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error listing verses: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, versesListResponse{Verses: verses, Limit: limit, Offset: offset})
+	}
+}
+
+// verseGetHandler serves GET /verses/{id}.
+func verseGetHandler(db *DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		v, ok, err := db.Get(r.Context(), id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error fetching verse: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("verse %q not found", id), http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, v)
+	}
+}
+
+// verseRandomHandler serves GET /verses/random.
+func verseRandomHandler(db *DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, ok, err := db.Random(r.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error fetching random verse: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			http.Error(w, "no verses available", http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, v)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("error encoding JSON response", "error", err)
+	}
+}